@@ -0,0 +1,249 @@
+package gotimer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// NewOneTimerAt creates a single delayed function call to fn with v,
+// firing at the given wall-clock time rather than after a relative delay.
+// If when has already passed, it fires on the next scheduler wakeup.
+func NewOneTimerAt[T any](fn func(T), v T, when time.Time) *OneTimer[T] {
+	d := time.Until(when)
+	if d < 0 {
+		d = 0
+	}
+	return NewOneTimer(fn, v, &d)
+}
+
+// NewIntervalTimerAt creates a repeating timer whose first fire happens at
+// firstAt, then continues on interval from there. If firstAt has already
+// passed, the first fire happens on the next scheduler wakeup.
+func NewIntervalTimerAt[T any](fn func(T), v T, firstAt time.Time, interval time.Duration) *IntervalTimer[T] {
+	d := time.Until(firstAt)
+	if d < 0 {
+		d = 0
+	}
+	return NewIntervalTimer(fn, v, &d, interval)
+}
+
+// cronField is a parsed cron field: either "any" (a bare *) or an explicit
+// set of allowed values built from comma-separated values, ranges (a-b),
+// and steps (*/n or a-b/n).
+type cronField struct {
+	any bool
+	set map[int]bool
+}
+
+func (f cronField) match(v int) bool {
+	if f.any {
+		return true
+	}
+	return f.set[v]
+}
+
+func parseCronField(s string, min, max int) (cronField, error) {
+	if s == "*" {
+		return cronField{any: true}, nil
+	}
+	f := cronField{set: make(map[int]bool)}
+	for _, part := range strings.Split(s, ",") {
+		base, step := part, 1
+		if idx := strings.IndexByte(part, '/'); idx >= 0 {
+			base = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return cronField{}, fmt.Errorf("gotimer: invalid step in cron field %q", s)
+			}
+			step = n
+		}
+		var lo, hi int
+		switch {
+		case base == "*":
+			lo, hi = min, max
+		case strings.Contains(base, "-"):
+			bounds := strings.SplitN(base, "-", 2)
+			a, err1 := strconv.Atoi(bounds[0])
+			b, err2 := strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || a > b {
+				return cronField{}, fmt.Errorf("gotimer: invalid range in cron field %q", s)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(base)
+			if err != nil {
+				return cronField{}, fmt.Errorf("gotimer: invalid value in cron field %q", s)
+			}
+			lo, hi = n, n
+		}
+		if lo < min || hi > max {
+			return cronField{}, fmt.Errorf("gotimer: cron field %q out of range [%d,%d]", s, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			f.set[v] = true
+		}
+	}
+	return f, nil
+}
+
+// cronSpec is a parsed 5-field cron expression: minute, hour,
+// day-of-month, month, and day-of-week.
+type cronSpec struct {
+	minute, hour, dom, month, dow cronField
+}
+
+func parseCronSpec(spec string) (cronSpec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return cronSpec{}, fmt.Errorf("gotimer: cron spec must have 5 fields (minute hour dom month dow), got %d", len(fields))
+	}
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return cronSpec{}, err
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return cronSpec{}, err
+	}
+	dom, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return cronSpec{}, err
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return cronSpec{}, err
+	}
+	dow, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return cronSpec{}, err
+	}
+	return cronSpec{minute: minute, hour: hour, dom: dom, month: month, dow: dow}, nil
+}
+
+// matches reports whether t falls on a minute this spec fires on. Per
+// standard cron semantics, when both day-of-month and day-of-week are
+// restricted (not "*"), a match on either one is enough; when only one is
+// restricted, only that one has to match.
+func (cs cronSpec) matches(t time.Time) bool {
+	if !cs.minute.match(t.Minute()) || !cs.hour.match(t.Hour()) || !cs.month.match(int(t.Month())) {
+		return false
+	}
+	domOK, dowOK := cs.dom.match(t.Day()), cs.dow.match(int(t.Weekday()))
+	if cs.dom.any || cs.dow.any {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// maxCronScan bounds how far into the future next will search for a match,
+// so a spec that (due to a DST quirk or operator typo) never matches
+// doesn't scan forever.
+const maxCronScan = 5 * 366 * 24 * 60
+
+// next finds the first whole minute strictly after from that this spec
+// matches, walking minute by minute in from's location so DST transitions
+// are handled the same way the Go runtime handles wall-clock time.
+func (cs cronSpec) next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < maxCronScan; i++ {
+		if cs.matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return t
+}
+
+// Cron calls fn with v at every wall-clock instant matching a 5-field cron
+// expression (minute hour day-of-month month day-of-week, supporting *,
+// ",", "-", and "/"), re-queuing itself for the next match after each
+// fire. loc controls which time zone the spec is evaluated in; pass nil
+// for time.Local.
+type Cron[T any] struct {
+	c    time.Time
+	fa   time.Time
+	i    time.Duration
+	fn   func(T)
+	t    T
+	spec cronSpec
+	loc  *time.Location
+	hi   int
+	sh   *scheduler
+	st   atomic.Uint32
+}
+
+// NewCron parses spec and schedules fn(v) to run at every matching
+// wall-clock instant in loc (time.Local if loc is nil). Each fire runs fn
+// in its own goroutine rather than on the scheduler's shard, so fn is
+// free to call Stop on this same Cron without deadlocking the shard
+// that's driving it.
+func NewCron[T any](fn func(T), v T, spec string, loc *time.Location) (*Cron[T], error) {
+	cs, err := parseCronSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	c := &Cron[T]{
+		c:    time.Now().UTC(),
+		fn:   fn,
+		t:    v,
+		spec: cs,
+		loc:  loc,
+		hi:   -1,
+	}
+	c.fa = cs.next(time.Now().In(loc))
+	queue(c)
+	return c, nil
+}
+
+func (self *Cron[T]) getCreated() time.Time {
+	return self.c
+}
+
+// getInterval only needs to report "this timer repeats" to the scheduler;
+// unlike IntervalTimer, Cron's next fire time isn't a fixed duration away,
+// so the pointed-to value itself is never read.
+func (self *Cron[T]) getInterval() *time.Duration {
+	return &self.i
+}
+func (self *Cron[T]) fireAt() time.Time {
+	return self.fa
+}
+func (self *Cron[T]) setFireAt(t time.Time) {
+	self.fa = t
+}
+func (self *Cron[T]) tick(t time.Time) {
+	// fire may run this up to earlyFireWindow before fa; searching from fa
+	// in that case keeps the next fire off the minute that just fired.
+	from := t
+	if from.Before(self.fa) {
+		from = self.fa
+	}
+	self.fa = self.spec.next(from.In(self.loc))
+	go self.fn(self.t)
+}
+func (self *Cron[T]) status() *atomic.Uint32 {
+	return &self.st
+}
+func (self *Cron[T]) index() int {
+	return self.hi
+}
+func (self *Cron[T]) setIndex(i int) {
+	self.hi = i
+}
+func (self *Cron[T]) shard() *scheduler {
+	return self.sh
+}
+func (self *Cron[T]) setShard(s *scheduler) {
+	self.sh = s
+}
+
+// Stop prevents the cron job from firing again.
+func (self *Cron[T]) Stop() bool {
+	return stopTimer(self)
+}
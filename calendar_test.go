@@ -0,0 +1,80 @@
+package gotimer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSpecNextEveryMinute(t *testing.T) {
+	cs, err := parseCronSpec("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 7, 26, 10, 30, 15, 0, time.UTC)
+	got := cs.next(from)
+	want := time.Date(2026, 7, 26, 10, 31, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestCronSpecNextDailyAtMidnight(t *testing.T) {
+	cs, err := parseCronSpec("0 0 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 7, 26, 23, 59, 0, 0, time.UTC)
+	got := cs.next(from)
+	want := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestCronSpecStepAndRange(t *testing.T) {
+	cs, err := parseCronSpec("*/15 9-17 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC) // a Monday
+	if !cs.matches(mon) {
+		t.Fatalf("expected match at %v", mon)
+	}
+	sat := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	if cs.matches(sat) {
+		t.Fatalf("did not expect a match on the weekend %v", sat)
+	}
+	offStep := time.Date(2026, 7, 27, 9, 7, 0, 0, time.UTC)
+	if cs.matches(offStep) {
+		t.Fatalf("did not expect a match off the 15-minute step %v", offStep)
+	}
+}
+
+func TestCronSpecInvalid(t *testing.T) {
+	if _, err := parseCronSpec("* * *"); err == nil {
+		t.Fatal("expected an error for the wrong field count")
+	}
+	if _, err := parseCronSpec("60 * * * *"); err == nil {
+		t.Fatal("expected an error for an out-of-range minute")
+	}
+}
+
+func TestNewCronWaitsForTheNextMinuteBoundary(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	c, err := NewCron(func(int) {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	}, 0, "* * * * *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	select {
+	case <-fired:
+		t.Fatal("should not fire before the next minute boundary")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
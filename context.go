@@ -0,0 +1,61 @@
+package gotimer
+
+import (
+	"context"
+	"time"
+)
+
+// watchContext registers t with its shard so that cancelling ctx stops t.
+// A context with a nil Done() channel (context.Background, context.TODO)
+// never cancels, so there is nothing to watch.
+func watchContext(ctx context.Context, t timer) {
+	if ctx.Done() == nil {
+		return
+	}
+	t.shard().ctxAdd <- ctxWaiter{ctx: ctx, t: t}
+}
+
+// NewOneTimerWithContext behaves like NewOneTimer, except fn is not called
+// once ctx is done, and cancelling ctx stops the timer the same way
+// calling Stop would.
+func NewOneTimerWithContext[T any](ctx context.Context, fn func(T), v T, delay *time.Duration) *OneTimer[T] {
+	t := NewOneTimer(func(val T) {
+		if ctx.Err() != nil {
+			return
+		}
+		fn(val)
+	}, v, delay)
+	watchContext(ctx, t)
+	return t
+}
+
+// NewIntervalTimerWithContext behaves like NewIntervalTimer, except fn is
+// not called once ctx is done, and cancelling ctx stops the timer the same
+// way calling Stop would.
+func NewIntervalTimerWithContext[T any](ctx context.Context, fn func(T), v T, delay *time.Duration, interval time.Duration) *IntervalTimer[T] {
+	t := NewIntervalTimer(func(val T) {
+		if ctx.Err() != nil {
+			return
+		}
+		fn(val)
+	}, v, delay, interval)
+	watchContext(ctx, t)
+	return t
+}
+
+// AfterContext waits for d to elapse or ctx to be done, whichever happens
+// first, letting callers write select-free timeouts. It returns nil if the
+// timer fired, or ctx.Err() if ctx was done first.
+func AfterContext(ctx context.Context, d time.Duration) error {
+	done := make(chan struct{})
+	NewOneTimerWithContext(ctx, func(struct{}) {
+		close(done)
+	}, struct{}{}, &d)
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package gotimer
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOneTimerWithContextSkipsFnAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var fired int32
+	d := 20 * time.Millisecond
+	NewOneTimerWithContext(ctx, func(int) {
+		atomic.AddInt32(&fired, 1)
+	}, 0, &d)
+
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&fired) != 0 {
+		t.Fatalf("fn should not run after ctx is cancelled, got %d calls", fired)
+	}
+}
+
+func TestOneTimerWithContextFiresWithoutCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var fired int32
+	d := 5 * time.Millisecond
+	NewOneTimerWithContext(ctx, func(int) {
+		atomic.AddInt32(&fired, 1)
+	}, 0, &d)
+
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&fired) != 1 {
+		t.Fatalf("expected fn to run once, got %d", fired)
+	}
+}
+
+func TestAfterContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := AfterContext(ctx, 50*time.Millisecond); err == nil {
+		t.Fatal("expected a context deadline error")
+	}
+
+	if err := AfterContext(context.Background(), 10*time.Millisecond); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
+
+func TestTwoTimersShareOneCancellableContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var fired1, fired2 int32
+	d1, d2 := 20*time.Millisecond, 25*time.Millisecond
+	t1 := NewOneTimerWithContext(ctx, func(int) {
+		atomic.AddInt32(&fired1, 1)
+	}, 0, &d1)
+	t2 := NewOneTimerWithContext(ctx, func(int) {
+		atomic.AddInt32(&fired2, 1)
+	}, 0, &d2)
+
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+
+	if atomic.LoadInt32(&fired1) != 0 {
+		t.Fatalf("first timer should not run after ctx is cancelled, got %d calls", fired1)
+	}
+	if atomic.LoadInt32(&fired2) != 0 {
+		t.Fatalf("second timer should not run after ctx is cancelled, got %d calls", fired2)
+	}
+	if t1.status().Load() != timerRemoved {
+		t.Fatalf("first timer should be timerRemoved, got status %d", t1.status().Load())
+	}
+	if t2.status().Load() != timerRemoved {
+		t.Fatalf("second timer should be timerRemoved, got status %d", t2.status().Load())
+	}
+}
+
+func TestManyContextWaitersOnOneShard(t *testing.T) {
+	SetShardCount(1)
+	defer SetShardCount(runtime.GOMAXPROCS(0))
+
+	var fired int32
+	for i := 0; i < 50; i++ {
+		d := time.Duration(i) * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		NewOneTimerWithContext(ctx, func(int) {
+			atomic.AddInt32(&fired, 1)
+		}, 0, &d)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&fired); n != 50 {
+		t.Fatalf("expected all 50 context-bound timers to fire, got %d", n)
+	}
+}
@@ -5,85 +5,379 @@
 package gotimer
 
 import (
+	"container/heap"
+	"context"
 	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// earlyFireWindow is how close to a timer's fire time the scheduler will
+// wake up and fire it immediately, rather than resleeping for a few
+// leftover nanoseconds. This keeps interval timers accurate without the
+// scheduler spinning on a string of near-zero sleeps.
+const earlyFireWindow = time.Millisecond
+
+// Timer status values, modeled after the Go runtime's pending-timer state
+// machine (runtime.timer.status). Stop, Reset and fire claim a timer via
+// CAS, so a Stop or Reset racing with an in-flight tick can tell whether it
+// actually won; the final state after a claim is then set with a plain Store.
+const (
+	timerWaiting   uint32 = iota // sitting in a shard's heap, not yet due
+	timerRunning                 // popped from the heap, tick in flight
+	timerModifying               // claimed by a concurrent Stop/Reset
+	timerRemoved                 // stopped, or an idle ThrottleTimer; fires again only after Reset or Set
+	timerDeleted                 // a OneTimer or ThrottleTimer that has already fired
+)
+
 type scheduler struct {
-	timers []timer
+	idx    int
+	heap   timerHeap
 	stop   chan bool
 	queue  chan timer
+	cancel chan timer
+	reset  chan resetRequest
+	ctxAdd chan ctxWaiter
+
+	// ctxWaiters holds the timers registered via *WithContext, keyed by
+	// the context they watch; a context can be shared by more than one
+	// timer, so each key maps to a slice. poll folds ctx.Done() for each
+	// key straight into its own select via reflect, so cancellation is
+	// observed without spawning a goroutine per context.
+	ctxWaiters map[context.Context][]timer
+}
+
+// ctxWaiter registers t to be stopped when ctx is done.
+type ctxWaiter struct {
+	ctx context.Context
+	t   timer
+}
+
+// resetRequest asks a timer's shard to place it at a new fire time, and
+// optionally a new interval. Both are carried here, rather than written
+// onto t by the caller, because t's fireAt and interval fields are
+// otherwise only ever touched by the shard's own poll goroutine (heap
+// order, the sleep deadline, and fire's re-queuing all read them without
+// synchronization).
+type resetRequest struct {
+	t        timer
+	at       time.Time
+	interval *time.Duration // nil unless the reset also changes the interval
 }
 
 type timer interface {
 	getInterval() *time.Duration
 	getCreated() time.Time
+	fireAt() time.Time
+	setFireAt(t time.Time)
+
+	// tick advances the timer's own scheduling state (e.g. the next fire
+	// time) and dispatches the user callback; it must return without
+	// waiting for that callback to finish, since it runs on the shard's
+	// own poll goroutine (see fire) and a callback that calls back into
+	// its own shard - Stop, Reset, Set, or queuing a new timer - would
+	// deadlock against a tick that was still waiting on it.
 	tick(t time.Time)
-	getStop() bool
+	status() *atomic.Uint32
+	index() int
+	setIndex(i int)
+	shard() *scheduler
+	setShard(s *scheduler)
+}
+
+// timerHeap is a container/heap.Interface ordered by fire time, mirroring
+// how the Go runtime keeps pending timers in a heap keyed on `when`.
+type timerHeap []timer
+
+func (h timerHeap) Len() int { return len(h) }
+func (h timerHeap) Less(i, j int) bool {
+	return h[i].fireAt().Before(h[j].fireAt())
+}
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].setIndex(i)
+	h[j].setIndex(j)
+}
+func (h *timerHeap) Push(x any) {
+	t := x.(timer)
+	t.setIndex(len(*h))
+	*h = append(*h, t)
+}
+func (h *timerHeap) Pop() any {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.setIndex(-1)
+	*h = old[:n-1]
+	return t
 }
 
-var _scheduler *scheduler
+var (
+	shardsMu     sync.Mutex
+	shards       []*scheduler
+	shardCounter atomic.Uint64
+)
 
-func instance() *scheduler {
-	if _scheduler == nil {
-		_scheduler = &scheduler{
-			timers: make([]timer, 0),
-			stop:   make(chan bool),
-			queue:  make(chan timer),
+// SetShardCount configures the number of independent scheduler shards that
+// timer creation is spread across, following the Go runtime's move from a
+// single global timer heap to per-P timer buckets. It discards any existing
+// shards, so call it once during startup before creating timers; timers
+// already running on the old shards keep ticking on them.
+func SetShardCount(n int) {
+	if n < 1 {
+		n = 1
+	}
+	shardsMu.Lock()
+	defer shardsMu.Unlock()
+	shards = make([]*scheduler, n)
+}
+
+// shardPool returns the slice of shards, lazily sizing it to
+// runtime.GOMAXPROCS(0) on first use if SetShardCount was never called.
+func shardPool() []*scheduler {
+	shardsMu.Lock()
+	defer shardsMu.Unlock()
+	if shards == nil {
+		n := runtime.GOMAXPROCS(0)
+		if n < 1 {
+			n = 1
 		}
-		go _scheduler.poll()
+		shards = make([]*scheduler, n)
 	}
-	return _scheduler
+	return shards
 }
 
+// pickShard round-robins across the shard pool via shardCounter, starting
+// each shard's goroutine lazily the first time it is selected.
+func pickShard() *scheduler {
+	pool := shardPool()
+	idx := int(shardCounter.Add(1) % uint64(len(pool)))
+
+	shardsMu.Lock()
+	defer shardsMu.Unlock()
+	s := pool[idx]
+	if s == nil {
+		s = &scheduler{
+			idx:        idx,
+			heap:       make(timerHeap, 0),
+			stop:       make(chan bool),
+			queue:      make(chan timer),
+			cancel:     make(chan timer),
+			reset:      make(chan resetRequest),
+			ctxAdd:     make(chan ctxWaiter),
+			ctxWaiters: make(map[context.Context][]timer),
+		}
+		pool[idx] = s
+		go s.poll()
+	}
+	return s
+}
+
+// Fixed case indices into the slice built by poll on every iteration; the
+// context waiters' Done() channels follow starting at numFixedCases.
+const (
+	caseStop = iota
+	caseQueue
+	caseCancel
+	caseReset
+	caseCtxAdd
+	caseWake
+	numFixedCases
+)
+
+// poll sleeps until the next timer is due instead of spinning, waking
+// early whenever a new timer is queued, stopped, or one of its
+// context-bound timers' contexts is cancelled. It uses reflect.Select to
+// fold an arbitrary number of ctx.Done() channels into the same select as
+// its fixed channels, so watching N contexts costs one goroutine, not N.
 func (s *scheduler) poll() {
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Printf("recovered from panic: %v", r)
 		}
 	}()
-	b := false
 	for {
-		select {
-		case <-s.stop:
-			b = true
-		case t := <-s.queue:
-			s.timers = append(s.timers, t)
+		var wake <-chan time.Time
+		var sleep *time.Timer
+		if len(s.heap) > 0 {
+			d := time.Until(s.heap[0].fireAt())
+			if d < earlyFireWindow {
+				d = 0
+			}
+			sleep = time.NewTimer(d)
+			wake = sleep.C
+		}
+
+		cases := make([]reflect.SelectCase, numFixedCases, numFixedCases+len(s.ctxWaiters))
+		cases[caseStop] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.stop)}
+		cases[caseQueue] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.queue)}
+		cases[caseCancel] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.cancel)}
+		cases[caseReset] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.reset)}
+		cases[caseCtxAdd] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctxAdd)}
+		cases[caseWake] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(wake)}
+		ctxs := make([]context.Context, 0, len(s.ctxWaiters))
+		for ctx := range s.ctxWaiters {
+			ctxs = append(ctxs, ctx)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+		}
+
+		chosen, recv, _ := reflect.Select(cases)
+		if sleep != nil {
+			sleep.Stop()
+		}
+		switch {
+		case chosen == caseStop:
+			shardsMu.Lock()
+			if shards[s.idx] == s {
+				shards[s.idx] = nil // let the next pick to this slot spin up a fresh shard
+			}
+			shardsMu.Unlock()
+			return
+		case chosen == caseQueue:
+			heap.Push(&s.heap, recv.Interface().(timer))
+		case chosen == caseCancel:
+			t := recv.Interface().(timer)
+			if t.index() >= 0 {
+				heap.Remove(&s.heap, t.index())
+			}
+			t.status().Store(timerRemoved)
+		case chosen == caseReset:
+			req := recv.Interface().(resetRequest)
+			// Only poll's own goroutine ever writes a timer's fireAt or
+			// interval, since both are read lock-free by Less/fire/the
+			// sleep deadline above.
+			req.t.setFireAt(req.at)
+			if req.interval != nil {
+				*req.t.getInterval() = *req.interval
+			}
+			if req.t.index() >= 0 {
+				heap.Fix(&s.heap, req.t.index())
+			} else {
+				heap.Push(&s.heap, req.t)
+			}
+		case chosen == caseCtxAdd:
+			w := recv.Interface().(ctxWaiter)
+			s.ctxWaiters[w.ctx] = append(s.ctxWaiters[w.ctx], w.t)
+		case chosen == caseWake:
+			s.fire(time.Now())
 		default:
-			del := make([]int, 0)
-			for i, t := range s.timers {
-				if t.getInterval() == nil {
-					t.tick(time.Now()) // it's a one-timer
-					del = append(del, i)
-				} else {
-					if t.getStop() {
-						del = append(del, i)
-					} else {
-						t.tick(time.Now())
-					}
+			ctx := ctxs[chosen-numFixedCases]
+			waiters := s.ctxWaiters[ctx]
+			delete(s.ctxWaiters, ctx)
+			for _, t := range waiters {
+				if t.index() >= 0 {
+					heap.Remove(&s.heap, t.index())
 				}
+				t.status().Store(timerRemoved)
 			}
-			for _, idx := range del {
-				s.timers = append(s.timers[:idx], s.timers[:idx+1]...)
+		}
+	}
+}
+
+// fire pops and ticks every timer whose fire time has passed (within
+// earlyFireWindow), re-queuing IntervalTimers that are still running.
+// Each timer is claimed with a CAS from timerWaiting to timerRunning so a
+// concurrent Stop or Reset can tell whether it won the race against this
+// tick (see stopTimer/resetTimer). tick itself only updates the timer's
+// own fields and hands the user callback off to its own goroutine, so
+// this loop never blocks on user code - a callback is free to Stop,
+// Reset, or queue a new timer against this same shard without
+// deadlocking the poll goroutine that's running it.
+func (s *scheduler) fire(now time.Time) {
+	deadline := now.Add(earlyFireWindow)
+	for len(s.heap) > 0 && s.heap[0].fireAt().Before(deadline) {
+		t := heap.Pop(&s.heap).(timer)
+		if !t.status().CompareAndSwap(timerWaiting, timerRunning) {
+			// a Stop/Reset claimed this timer between the due-check and now;
+			// it will finish removing or re-queuing the timer itself.
+			continue
+		}
+		t.tick(now)
+		if t.getInterval() != nil {
+			if t.status().CompareAndSwap(timerRunning, timerWaiting) {
+				heap.Push(&s.heap, t)
 			}
+		} else {
+			t.status().CompareAndSwap(timerRunning, timerDeleted)
 		}
-		if b {
-			break
+	}
+}
+
+func queue(t timer) {
+	s := pickShard()
+	t.setShard(s)
+	s.queue <- t
+}
+
+// stopTimer claims t away from its shard, matching time.Timer.Stop's
+// semantics: it returns true if it prevented a pending fire, false if the
+// timer had already fired, already been stopped, or is currently mid-tick.
+func stopTimer(t timer) bool {
+	for {
+		switch t.status().Load() {
+		case timerWaiting:
+			if t.status().CompareAndSwap(timerWaiting, timerModifying) {
+				t.shard().cancel <- t
+				return true
+			}
+		case timerRunning:
+			if t.status().CompareAndSwap(timerRunning, timerModifying) {
+				t.shard().cancel <- t
+				return false
+			}
+		case timerModifying:
+			runtime.Gosched() // another Stop/Reset is mid-flight; retry
+		default: // timerRemoved, timerDeleted
+			return false
 		}
 	}
-	_scheduler = nil // delete the scheduler as we have exited our loop
 }
 
-func queue(t timer, delay *time.Duration) {
-	s := instance()
-	if delay != nil {
-		go func() {
-			time.Sleep(*delay)
-			s.queue <- t
-		}()
-	} else {
-		s.queue <- t
+// resetTimer reschedules t to fire after delay, claiming it first so a
+// concurrent tick cannot run against a half-updated fire time. The new
+// fire time is written by t's own shard goroutine, not here, because
+// fireAt is read lock-free by that goroutine's heap ordering and sleep
+// deadline; writing it from the calling goroutine would race. resetTimer
+// returns true if it prevented a pending fire, matching time.Timer.Reset.
+func resetTimer(t timer, delay time.Duration) bool {
+	return resetTimerInterval(t, delay, nil)
+}
+
+// resetTimerInterval is resetTimer plus an optional new interval, for
+// timers like Ticker whose interval can also change on Reset. The new
+// interval, like the new fire time, is written by t's own shard goroutine
+// rather than here, for the same reason.
+func resetTimerInterval(t timer, delay time.Duration, newInterval *time.Duration) bool {
+	var prevented bool
+	for {
+		switch t.status().Load() {
+		case timerWaiting:
+			if t.status().CompareAndSwap(timerWaiting, timerModifying) {
+				prevented = true
+			} else {
+				continue
+			}
+		case timerRunning:
+			if t.status().CompareAndSwap(timerRunning, timerModifying) {
+				prevented = false
+			} else {
+				continue
+			}
+		case timerModifying:
+			runtime.Gosched()
+			continue
+		default: // timerRemoved, timerDeleted
+			prevented = false
+		}
+		break
 	}
+	t.status().Store(timerWaiting)
+	t.shard().reset <- resetRequest{t: t, at: time.Now().Add(delay), interval: newInterval}
+	return prevented
 }
 
 type IntervalTimer[T any] struct {
@@ -92,7 +386,9 @@ type IntervalTimer[T any] struct {
 	n  time.Time
 	fn func(T)
 	t  T
-	s  bool
+	hi int
+	sh *scheduler
+	st atomic.Uint32
 }
 
 func (self *IntervalTimer[T]) getCreated() time.Time {
@@ -101,20 +397,54 @@ func (self *IntervalTimer[T]) getCreated() time.Time {
 func (self *IntervalTimer[T]) getInterval() *time.Duration {
 	return &self.i
 }
-func (self *IntervalTimer[T]) getStop() bool {
-	return self.s
+func (self *IntervalTimer[T]) fireAt() time.Time {
+	return self.n
+}
+func (self *IntervalTimer[T]) setFireAt(t time.Time) {
+	self.n = t
 }
 func (self *IntervalTimer[T]) tick(t time.Time) {
-	if t.After(self.n) {
-		self.fn(self.t)
-		self.n = t.Add(self.i)
-	}
+	self.n = t.Add(self.i)
+	go self.fn(self.t)
+}
+func (self *IntervalTimer[T]) status() *atomic.Uint32 {
+	return &self.st
+}
+func (self *IntervalTimer[T]) index() int {
+	return self.hi
+}
+func (self *IntervalTimer[T]) setIndex(i int) {
+	self.hi = i
+}
+func (self *IntervalTimer[T]) shard() *scheduler {
+	return self.sh
+}
+func (self *IntervalTimer[T]) setShard(s *scheduler) {
+	self.sh = s
+}
+
+// Stop prevents the timer from firing again. It returns true if the call
+// stopped a pending fire, false if the timer had already fired, was already
+// stopped, or a tick was already in flight when Stop was called.
+func (self *IntervalTimer[T]) Stop() bool {
+	return stopTimer(self)
+}
+
+// Reset stops the timer, if running, and reschedules it to fire after
+// delay, then continue ticking on its usual interval. It returns true if
+// the call stopped a pending fire, matching time.Timer.Reset.
+func (self *IntervalTimer[T]) Reset(delay time.Duration) bool {
+	return resetTimer(self, delay)
 }
 
 type OneTimer[T any] struct {
 	c  time.Time
+	fa time.Time
 	fn func(T)
 	t  T
+	hi int
+	sh *scheduler
+	st atomic.Uint32
 }
 
 func (self *OneTimer[T]) getCreated() time.Time {
@@ -123,33 +453,84 @@ func (self *OneTimer[T]) getCreated() time.Time {
 func (self *OneTimer[T]) getInterval() *time.Duration {
 	return nil
 }
-func (self *OneTimer[T]) getStop() bool {
-	return true
+func (self *OneTimer[T]) fireAt() time.Time {
+	return self.fa
+}
+func (self *OneTimer[T]) setFireAt(t time.Time) {
+	self.fa = t
 }
 func (self *OneTimer[T]) tick(t time.Time) {
-	self.fn(self.t)
+	go self.fn(self.t)
+}
+func (self *OneTimer[T]) status() *atomic.Uint32 {
+	return &self.st
+}
+func (self *OneTimer[T]) index() int {
+	return self.hi
+}
+func (self *OneTimer[T]) setIndex(i int) {
+	self.hi = i
+}
+func (self *OneTimer[T]) shard() *scheduler {
+	return self.sh
+}
+func (self *OneTimer[T]) setShard(s *scheduler) {
+	self.sh = s
 }
 
-// NewIntervalTimer will create a repeating timer and call your supplied function `fn` with your supplied object `v` after `delay`
+// Stop prevents the timer from firing, if it hasn't already. It returns
+// true if the call stopped a pending fire, false if the timer had already
+// fired, was already stopped, or a tick was already in flight when Stop
+// was called.
+func (self *OneTimer[T]) Stop() bool {
+	return stopTimer(self)
+}
+
+// Reset reschedules the timer to fire after delay, even if it has already
+// fired or been stopped. It returns true if the call stopped a pending
+// fire, matching time.Timer.Reset.
+func (self *OneTimer[T]) Reset(delay time.Duration) bool {
+	return resetTimer(self, delay)
+}
+
+// NewIntervalTimer will create a repeating timer and call your supplied function `fn` with your supplied object `v` after `delay`.
+// Each fire runs fn in its own goroutine rather than on the scheduler's shard, so fn is free to call
+// Stop or Reset on this same timer (or queue a new one) without deadlocking the shard that's driving
+// it; it also means a slow fn can still be running when the next interval fires.
 func NewIntervalTimer[T any](fn func(T), v T, delay *time.Duration, interval time.Duration) *IntervalTimer[T] {
+	now := time.Now().UTC()
 	t := &IntervalTimer[T]{
-		c:  time.Now().UTC(),
+		c:  now,
 		i:  interval,
-		n:  time.Now().Add(interval),
 		t:  v,
 		fn: fn,
+		hi: -1,
+	}
+	if delay != nil {
+		t.n = now.Add(*delay)
+	} else {
+		t.n = now.Add(interval)
 	}
-	defer queue(t, delay) // defer so we don't block returning
+	defer queue(t) // defer so we don't block returning
 	return t
 }
 
-// NewOneTimer will create a single delayed function call to `fn` with your supplied object `v` after `delay`
+// NewOneTimer will create a single delayed function call to `fn` with your supplied object `v` after `delay`.
+// fn runs in its own goroutine rather than on the scheduler's shard, so it's free to queue a new timer
+// without deadlocking the shard that fired it.
 func NewOneTimer[T any](fn func(T), v T, delay *time.Duration) *OneTimer[T] {
+	now := time.Now().UTC()
 	t := &OneTimer[T]{
-		c:  time.Now().UTC(),
+		c:  now,
 		fn: fn,
 		t:  v,
+		hi: -1,
+	}
+	if delay != nil {
+		t.fa = now.Add(*delay)
+	} else {
+		t.fa = now
 	}
-	defer queue(t, delay) // defer so we don't block returning
+	defer queue(t) // defer so we don't block returning
 	return t
 }
@@ -0,0 +1,30 @@
+package gotimer
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// BenchmarkConcurrentTimerCreation measures how long it takes N goroutines
+// to each create one OneTimer concurrently, spread across the sharded
+// scheduler pool. Compare against git history before the sharding change
+// (a single global scheduler) by checking out that revision and rerunning.
+func BenchmarkConcurrentTimerCreation(b *testing.B) {
+	for _, n := range []int{40, 4000, 400000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			noop := func(int) {}
+			for i := 0; i < b.N; i++ {
+				var wg sync.WaitGroup
+				wg.Add(n)
+				for j := 0; j < n; j++ {
+					go func() {
+						defer wg.Done()
+						NewOneTimer(noop, 0, nil)
+					}()
+				}
+				wg.Wait()
+			}
+		})
+	}
+}
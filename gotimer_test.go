@@ -0,0 +1,108 @@
+package gotimer
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestQueueTimerFromWithinCallbackOnSameShard creates a new OneTimer from
+// inside another timer's callback, both pinned to the same shard. Before
+// tick dispatched callbacks onto their own goroutine, this deadlocked:
+// the inner NewOneTimer's queue send had no one left to receive it, since
+// the only goroutine that could have (this shard's poll) was blocked
+// running the outer callback that made the call.
+func TestQueueTimerFromWithinCallbackOnSameShard(t *testing.T) {
+	SetShardCount(1)
+	defer SetShardCount(runtime.GOMAXPROCS(0))
+
+	done := make(chan struct{})
+	d1 := 5 * time.Millisecond
+	NewOneTimer(func(int) {
+		d2 := 5 * time.Millisecond
+		NewOneTimer(func(int) {
+			close(done)
+		}, 0, &d2)
+	}, 0, &d1)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadlocked: inner timer never fired")
+	}
+}
+
+// TestIntervalTimerStopDuringFire stops a running IntervalTimer while a
+// tick may be in flight and asserts that at most one more call (the
+// callback of a tick already dispatched when Stop ran) lands afterward,
+// whether or not Stop won the race against that in-flight tick.
+func TestIntervalTimerStopDuringFire(t *testing.T) {
+	var fires int32
+	d := 5 * time.Millisecond
+	it := NewIntervalTimer(func(int) {
+		atomic.AddInt32(&fires, 1)
+		time.Sleep(2 * time.Millisecond)
+	}, 0, &d, d)
+
+	time.Sleep(7 * time.Millisecond)
+	it.Stop()
+
+	before := atomic.LoadInt32(&fires)
+	time.Sleep(30 * time.Millisecond)
+	after := atomic.LoadInt32(&fires)
+	if after != before && after != before+1 {
+		t.Fatalf("timer kept firing after Stop: %d -> %d", before, after)
+	}
+}
+
+// TestIntervalTimerStopFromWithinOwnCallback covers the realistic "stop
+// myself after N fires" pattern, where fn calls Stop on the very
+// IntervalTimer it was scheduled by. Before tick dispatched fn onto its
+// own goroutine, this deadlocked: stopTimer sent on the shard's cancel
+// channel, and the only goroutine that could ever receive it was the
+// one blocked running fn in the first place.
+func TestIntervalTimerStopFromWithinOwnCallback(t *testing.T) {
+	var itPtr atomic.Pointer[IntervalTimer[int]]
+	var stopped atomic.Bool
+	var fires atomic.Int32
+	done := make(chan struct{})
+	d := 20 * time.Millisecond
+	it := NewIntervalTimer(func(int) {
+		if fires.Add(1) == 2 && stopped.CompareAndSwap(false, true) {
+			itPtr.Load().Stop()
+			close(done)
+		}
+	}, 0, &d, d)
+	itPtr.Store(it)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadlocked: Stop() from within the timer's own callback never returned")
+	}
+}
+
+// TestOneTimerResetAfterStop covers resetting a timer that already fired:
+// time.Timer allows this, and so should OneTimer.
+func TestOneTimerResetAfterStop(t *testing.T) {
+	var fired int32
+	d := 5 * time.Millisecond
+	ot := NewOneTimer(func(int) {
+		atomic.AddInt32(&fired, 1)
+	}, 0, &d)
+
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&fired) != 1 {
+		t.Fatalf("expected one fire before reset, got %d", fired)
+	}
+	if ot.Stop() {
+		t.Fatalf("Stop on an already-fired timer should return false")
+	}
+
+	ot.Reset(5 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&fired) != 2 {
+		t.Fatalf("expected a second fire after Reset, got %d", fired)
+	}
+}
@@ -0,0 +1,130 @@
+package gotimer
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ThrottleTimer[T] coalesces bursts of Set() calls into at most one call to
+// fn per dur, the classic trailing-edge debounce used to rate-limit
+// expensive reactions (log flushes, UI repaints, gossip broadcasts). It is
+// a port of the throttle timer pattern from Tendermint's common package,
+// built on this module's scheduler instead of a dedicated goroutine.
+//
+// The first Set() arms a fire after dur; any further Set() calls made
+// before that fire are collapsed into the same pending fire. Once fn has
+// run, the timer goes idle again until the next Set().
+type ThrottleTimer[T any] struct {
+	c  time.Time
+	fa time.Time
+	d  time.Duration
+	fn func(T)
+	t  T
+	hi int
+	sh *scheduler
+	st atomic.Uint32
+
+	stopped atomic.Bool
+}
+
+// NewThrottleTimer creates a ThrottleTimer that calls fn(v) at most once
+// per dur. It starts idle; nothing fires until Set() is called. Each fire
+// runs fn in its own goroutine rather than on the scheduler's shard, so
+// fn is free to call Set, Unset, or Stop on this same timer without
+// deadlocking the shard that's driving it.
+func NewThrottleTimer[T any](fn func(T), v T, dur time.Duration) *ThrottleTimer[T] {
+	t := &ThrottleTimer[T]{
+		c:  time.Now().UTC(),
+		d:  dur,
+		fn: fn,
+		t:  v,
+		hi: -1,
+	}
+	t.st.Store(timerRemoved) // idle until the first Set()
+	return t
+}
+
+func (self *ThrottleTimer[T]) getCreated() time.Time {
+	return self.c
+}
+func (self *ThrottleTimer[T]) getInterval() *time.Duration {
+	return nil
+}
+func (self *ThrottleTimer[T]) fireAt() time.Time {
+	return self.fa
+}
+func (self *ThrottleTimer[T]) setFireAt(t time.Time) {
+	self.fa = t
+}
+func (self *ThrottleTimer[T]) tick(t time.Time) {
+	go self.fn(self.t)
+}
+func (self *ThrottleTimer[T]) status() *atomic.Uint32 {
+	return &self.st
+}
+func (self *ThrottleTimer[T]) index() int {
+	return self.hi
+}
+func (self *ThrottleTimer[T]) setIndex(i int) {
+	self.hi = i
+}
+func (self *ThrottleTimer[T]) shard() *scheduler {
+	return self.sh
+}
+func (self *ThrottleTimer[T]) setShard(s *scheduler) {
+	self.sh = s
+}
+
+// Set arms a fire of fn(v) after dur if the timer is idle, or coalesces
+// into the already-pending fire otherwise. It is a no-op once Stop has
+// been called.
+func (self *ThrottleTimer[T]) Set() {
+	if self.stopped.Load() {
+		return
+	}
+	for {
+		cur := self.st.Load()
+		switch cur {
+		case timerWaiting:
+			return // already pending; this Set() coalesces into it
+		case timerRunning, timerModifying:
+			runtime.Gosched() // fire or another Set/Unset is mid-flight; retry
+		default: // timerRemoved, timerDeleted: idle, arm a fresh fire
+			if !self.st.CompareAndSwap(cur, timerWaiting) {
+				continue
+			}
+			self.fa = time.Now().Add(self.d)
+			if self.sh == nil {
+				self.sh = pickShard()
+			}
+			self.sh.queue <- self
+			return
+		}
+	}
+}
+
+// Unset cancels the pending fire, if any, leaving the timer idle so a
+// later Set() arms it again.
+func (self *ThrottleTimer[T]) Unset() {
+	for {
+		switch self.st.Load() {
+		case timerWaiting:
+			if self.st.CompareAndSwap(timerWaiting, timerModifying) {
+				self.sh.cancel <- self
+				return
+			}
+		case timerModifying:
+			runtime.Gosched()
+		default:
+			return // not armed; nothing to cancel
+		}
+	}
+}
+
+// Stop cancels the pending fire, if any, and permanently disables the
+// timer: subsequent Set() calls become no-ops.
+func (self *ThrottleTimer[T]) Stop() {
+	self.stopped.Store(true)
+	self.Unset()
+}
@@ -0,0 +1,92 @@
+package gotimer
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleTimerCoalescesBursts(t *testing.T) {
+	var calls int32
+	tt := NewThrottleTimer(func(int) {
+		atomic.AddInt32(&calls, 1)
+	}, 0, 20*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		tt.Set()
+		time.Sleep(2 * time.Millisecond)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected exactly 1 call after a burst of Set(), got %d", n)
+	}
+
+	// idle again after firing; a fresh Set() should fire again
+	tt.Set()
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 2 calls after re-arming, got %d", n)
+	}
+}
+
+// TestThrottleTimerStopAndUnsetFromWithinFn covers calling Stop and Unset
+// on a ThrottleTimer from inside its own fn, an obvious way to use this
+// type ("stop throttling once some condition is met"), and checks that
+// both calls return while fn is still running. Calling Set from inside fn
+// is not exercised here.
+func TestThrottleTimerStopAndUnsetFromWithinFn(t *testing.T) {
+	SetShardCount(1)
+	defer SetShardCount(runtime.GOMAXPROCS(0))
+
+	stopDone := make(chan struct{})
+	var stopPtr atomic.Pointer[ThrottleTimer[int]]
+	stopTT := NewThrottleTimer(func(int) {
+		stopPtr.Load().Stop()
+		close(stopDone)
+	}, 0, 5*time.Millisecond)
+	stopPtr.Store(stopTT)
+	stopTT.Set()
+
+	select {
+	case <-stopDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadlocked: Stop() from within fn never returned")
+	}
+
+	unsetDone := make(chan struct{})
+	var unsetPtr atomic.Pointer[ThrottleTimer[int]]
+	unsetTT := NewThrottleTimer(func(int) {
+		unsetPtr.Load().Unset()
+		close(unsetDone)
+	}, 0, 5*time.Millisecond)
+	unsetPtr.Store(unsetTT)
+	unsetTT.Set()
+
+	select {
+	case <-unsetDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadlocked: Unset() from within fn never returned")
+	}
+}
+
+func TestThrottleTimerUnsetAndStop(t *testing.T) {
+	var calls int32
+	tt := NewThrottleTimer(func(int) {
+		atomic.AddInt32(&calls, 1)
+	}, 0, 10*time.Millisecond)
+
+	tt.Set()
+	tt.Unset()
+	time.Sleep(25 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("expected 0 calls after Unset, got %d", n)
+	}
+
+	tt.Stop()
+	tt.Set()
+	time.Sleep(25 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("expected Set() after Stop to be a no-op, got %d calls", n)
+	}
+}
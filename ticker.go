@@ -0,0 +1,107 @@
+package gotimer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Ticker delivers ticks on C at roughly every interval, matching
+// time.Ticker's ergonomics but backed by this module's shared scheduler
+// instead of a dedicated goroutine per ticker. A slow receiver does not
+// back-pressure the scheduler: a full C is left as-is and the tick is
+// simply dropped, the same tradeoff the standard library makes.
+type Ticker struct {
+	C <-chan time.Time
+
+	c   chan time.Time
+	crt time.Time
+	i   time.Duration
+	n   time.Time
+	hi  int
+	sh  *scheduler
+	st  atomic.Uint32
+}
+
+// NewTicker creates a Ticker that sends the current time on C every
+// interval, starting after the first interval has elapsed.
+func NewTicker(interval time.Duration) *Ticker {
+	ch := make(chan time.Time, 1)
+	t := &Ticker{
+		C:   ch,
+		c:   ch,
+		crt: time.Now().UTC(),
+		i:   interval,
+		n:   time.Now().Add(interval),
+		hi:  -1,
+	}
+	queue(t)
+	return t
+}
+
+func (self *Ticker) getCreated() time.Time {
+	return self.crt
+}
+func (self *Ticker) getInterval() *time.Duration {
+	return &self.i
+}
+func (self *Ticker) fireAt() time.Time {
+	return self.n
+}
+func (self *Ticker) setFireAt(t time.Time) {
+	self.n = t
+}
+func (self *Ticker) tick(t time.Time) {
+	self.n = t.Add(self.i)
+	select {
+	case self.c <- t:
+	default: // slow consumer; drop this tick rather than block the scheduler
+	}
+}
+func (self *Ticker) status() *atomic.Uint32 {
+	return &self.st
+}
+func (self *Ticker) index() int {
+	return self.hi
+}
+func (self *Ticker) setIndex(i int) {
+	self.hi = i
+}
+func (self *Ticker) shard() *scheduler {
+	return self.sh
+}
+func (self *Ticker) setShard(s *scheduler) {
+	self.sh = s
+}
+
+// Stop turns off the ticker. No more ticks will be sent after Stop
+// returns, though Stop does not close C.
+func (self *Ticker) Stop() {
+	stopTimer(self)
+}
+
+// Reset stops the ticker and reconfigures it to tick every d, starting
+// after the next d has elapsed.
+func (self *Ticker) Reset(d time.Duration) {
+	resetTimerInterval(self, d, &d)
+}
+
+// After waits for the duration to elapse and then sends the current time
+// on the returned channel, a drop-in replacement for time.After backed by
+// this module's shared scheduler rather than a dedicated goroutine.
+func After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	NewOneTimer(func(struct{}) {
+		select {
+		case ch <- time.Now():
+		default:
+		}
+	}, struct{}{}, &d)
+	return ch
+}
+
+// Tick is a convenience wrapper around NewTicker for callers that never
+// need to stop the ticker, matching time.Tick. As with time.Tick, the
+// underlying Ticker cannot be garbage collected or stopped.
+func Tick(d time.Duration) <-chan time.Time {
+	return NewTicker(d).C
+}
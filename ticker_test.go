@@ -0,0 +1,50 @@
+package gotimer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickerTicksAndStops(t *testing.T) {
+	tk := NewTicker(10 * time.Millisecond)
+	defer tk.Stop()
+
+	n := 0
+	timeout := time.After(60 * time.Millisecond)
+loop:
+	for {
+		select {
+		case <-tk.C:
+			n++
+		case <-timeout:
+			break loop
+		}
+	}
+	if n < 3 {
+		t.Fatalf("expected at least 3 ticks in 60ms at a 10ms interval, got %d", n)
+	}
+
+	tk.Stop()
+	select {
+	case <-tk.C: // a tick sent before Stop returned may still be buffered
+	default:
+	}
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-tk.C:
+		t.Fatalf("received a tick after Stop")
+	default:
+	}
+}
+
+func TestAfterAndTick(t *testing.T) {
+	start := time.Now()
+	<-After(15 * time.Millisecond)
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatalf("After fired too early")
+	}
+
+	ch := Tick(10 * time.Millisecond)
+	<-ch
+	<-ch
+}